@@ -0,0 +1,189 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Joe-Albert-1703/lottery-scraper/internal/archivestore"
+)
+
+// ArchiveManifest and ArchiveDraw are aliases for the shared archivestore
+// types, kept under their original names here since they're part of this
+// file's exported API (e.g. json.Marshal call sites elsewhere in the
+// package refer to them).
+type ArchiveManifest = archivestore.Manifest
+
+// ArchiveDraw is one draw's entry in an ArchiveManifest.
+type ArchiveDraw = archivestore.Draw
+
+// writeArchive streams a self-contained .lottery.zip for draws: a
+// manifest.json plus one PDF per draw that has one archived, so an operator
+// can seed a fresh instance or re-run parseLotteryNumbers against history
+// without re-scraping the government site.
+func writeArchive(w io.Writer, draws []DrawRecord) error {
+	zw := zip.NewWriter(w)
+
+	manifest := ArchiveManifest{GeneratedAt: time.Now().UTC()}
+	for i, draw := range draws {
+		entry := ArchiveDraw{
+			LotteryName: draw.LotteryName,
+			DrawDate:    draw.DrawDate,
+			PdfLink:     draw.PdfLink,
+			Results:     draw.Results,
+		}
+		if len(draw.PDFContent) > 0 {
+			entry.PdfPath = fmt.Sprintf("pdfs/%d.pdf", i)
+			pw, err := zw.Create(entry.PdfPath)
+			if err != nil {
+				return fmt.Errorf("failed to add pdf to archive: %w", err)
+			}
+			if _, err := pw.Write(draw.PDFContent); err != nil {
+				return fmt.Errorf("failed to write pdf to archive: %w", err)
+			}
+		}
+		manifest.Draws = append(manifest.Draws, entry)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to add manifest to archive: %w", err)
+	}
+	if _, err := mw.Write(manifestJSON); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return zw.Close()
+}
+
+// readArchive parses a .lottery.zip previously produced by writeArchive,
+// returning the manifest and a map from PdfPath to PDF bytes.
+func readArchive(r *zip.Reader) (ArchiveManifest, map[string][]byte, error) {
+	var manifest ArchiveManifest
+	pdfs := make(map[string][]byte)
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+
+		if f.Name == "manifest.json" {
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				return manifest, nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			continue
+		}
+		pdfs[f.Name] = content
+	}
+
+	return manifest, pdfs, nil
+}
+
+// reformatDrawDate converts a store dateKey ("2006-01-02") back into the
+// "02/01/2006" form SaveDraw expects on a WebScrape.
+func reformatDrawDate(isoDate string) string {
+	t, err := time.Parse("2006-01-02", isoDate)
+	if err != nil {
+		return isoDate
+	}
+	return t.Format("02/01/2006")
+}
+
+// adminExport serves POST /admin/export?from=&to=, streaming a .lottery.zip
+// of every archived draw in the range.
+func (s *Server) adminExport(w http.ResponseWriter, r *http.Request) {
+	if !authorizedAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "archive not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" {
+		from = "0000-01-01"
+	}
+	if to == "" {
+		to = "9999-12-31"
+	}
+
+	draws, err := s.store.DrawsInRange(from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to export draws: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="archive.lottery.zip"`)
+	if err := writeArchive(w, draws); err != nil {
+		log.Printf("Failed to write archive: %v", err)
+	}
+}
+
+// adminImport serves POST /admin/import, accepting a .lottery.zip produced
+// by adminExport or cmd/lotteryball and archiving every draw inside it.
+func (s *Server) adminImport(w http.ResponseWriter, r *http.Request) {
+	if !authorizedAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "archive not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		http.Error(w, "not a valid zip archive", http.StatusBadRequest)
+		return
+	}
+
+	manifest, pdfs, err := readArchive(zr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read archive: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	imported := 0
+	for _, entry := range manifest.Draws {
+		lottery := WebScrape{
+			LotteryName: entry.LotteryName,
+			LotteryDate: reformatDrawDate(entry.DrawDate),
+			PdfLink:     entry.PdfLink,
+		}
+		if err := s.store.SaveDraw(lottery, entry.Results, pdfs[entry.PdfPath]); err != nil {
+			log.Printf("Failed to import draw %s/%s: %v", entry.LotteryName, entry.DrawDate, err)
+			continue
+		}
+		imported++
+	}
+
+	w.Header().Set(contentHeader, contentType)
+	json.NewEncoder(w).Encode(struct {
+		Imported int `json:"imported"`
+	}{Imported: imported})
+}
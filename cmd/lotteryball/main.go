@@ -0,0 +1,183 @@
+// Command lotteryball produces and consumes the same .lottery.zip archive
+// format as the server's /admin/export and /admin/import endpoints (sharing
+// the schema and upsert logic via internal/archivestore), so operators can
+// back up a whole season of draws, seed a fresh instance, or re-run the
+// parser against historical PDFs, all offline.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Joe-Albert-1703/lottery-scraper/internal/archivestore"
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: lotteryball <export|import> [flags]")
+	}
+
+	switch os.Args[1] {
+	case "export":
+		cmd := flag.NewFlagSet("export", flag.ExitOnError)
+		dbPath := cmd.String("db", "lottery.db", "path to the SQLite archive")
+		outPath := cmd.String("out", "archive.lottery.zip", "output archive path")
+		from := cmd.String("from", "0000-01-01", "start date (YYYY-MM-DD)")
+		to := cmd.String("to", "9999-12-31", "end date (YYYY-MM-DD)")
+		cmd.Parse(os.Args[2:])
+
+		if err := runExport(*dbPath, *outPath, *from, *to); err != nil {
+			log.Fatalf("export failed: %v", err)
+		}
+	case "import":
+		cmd := flag.NewFlagSet("import", flag.ExitOnError)
+		dbPath := cmd.String("db", "lottery.db", "path to the SQLite archive")
+		inPath := cmd.String("in", "archive.lottery.zip", "input archive path")
+		cmd.Parse(os.Args[2:])
+
+		if err := runImport(*dbPath, *inPath); err != nil {
+			log.Fatalf("import failed: %v", err)
+		}
+	default:
+		log.Fatalf("unknown command %q, expected export or import", os.Args[1])
+	}
+}
+
+func runExport(dbPath, outPath, from, to string) error {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dbPath, err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(
+		`SELECT id, lottery_name, draw_date, pdf_link, pdf_content FROM draws
+		 WHERE draw_date BETWEEN ? AND ? ORDER BY draw_date ASC`, from, to,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query draws: %w", err)
+	}
+	defer rows.Close()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outPath, err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	manifest := archivestore.Manifest{GeneratedAt: time.Now().UTC()}
+
+	for rows.Next() {
+		var id int64
+		var lotteryName, drawDate, pdfLink string
+		var pdfContent []byte
+		if err := rows.Scan(&id, &lotteryName, &drawDate, &pdfLink, &pdfContent); err != nil {
+			return fmt.Errorf("failed to scan draw: %w", err)
+		}
+
+		results, err := archivestore.LoadPrizes(db, id)
+		if err != nil {
+			return err
+		}
+
+		entry := archivestore.Draw{LotteryName: lotteryName, DrawDate: drawDate, PdfLink: pdfLink, Results: results}
+		if len(pdfContent) > 0 {
+			entry.PdfPath = fmt.Sprintf("pdfs/%d.pdf", id)
+			pw, err := zw.Create(entry.PdfPath)
+			if err != nil {
+				return fmt.Errorf("failed to add pdf to archive: %w", err)
+			}
+			if _, err := pw.Write(pdfContent); err != nil {
+				return fmt.Errorf("failed to write pdf to archive: %w", err)
+			}
+		}
+		manifest.Draws = append(manifest.Draws, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to add manifest to archive: %w", err)
+	}
+	if _, err := mw.Write(manifestJSON); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d draws to %s", len(manifest.Draws), outPath)
+	return nil
+}
+
+func runImport(dbPath, inPath string) error {
+	content, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inPath, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("not a valid zip archive: %w", err)
+	}
+
+	var manifest archivestore.Manifest
+	pdfs := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		if f.Name == "manifest.json" {
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			continue
+		}
+		pdfs[f.Name] = data
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dbPath, err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(archivestore.Schema); err != nil {
+		return fmt.Errorf("failed to ensure schema: %w", err)
+	}
+
+	imported := 0
+	for _, entry := range manifest.Draws {
+		err := archivestore.UpsertDraw(db, entry.LotteryName, entry.DrawDate, entry.PdfLink, time.Now().UTC(), pdfs[entry.PdfPath], entry.Results)
+		if err != nil {
+			log.Printf("Failed to import draw %s/%s: %v", entry.LotteryName, entry.DrawDate, err)
+			continue
+		}
+		imported++
+	}
+
+	log.Printf("Imported %d draws into %s", imported, dbPath)
+	return nil
+}
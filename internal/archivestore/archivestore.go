@@ -0,0 +1,122 @@
+// Package archivestore holds the SQLite schema and draw/prize upsert logic
+// shared by the server's SQLiteStore and cmd/lotteryball, so the two copies
+// of the archive format can't drift apart.
+package archivestore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Schema creates the draws/prizes tables used by the SQLite archive if they
+// don't already exist.
+const Schema = `
+CREATE TABLE IF NOT EXISTS draws (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	lottery_name TEXT NOT NULL,
+	draw_date    TEXT NOT NULL,
+	pdf_link     TEXT,
+	scraped_at   DATETIME NOT NULL,
+	pdf_content  BLOB,
+	UNIQUE(lottery_name, draw_date)
+);
+CREATE TABLE IF NOT EXISTS prizes (
+	draw_id  INTEGER NOT NULL REFERENCES draws(id),
+	position TEXT NOT NULL,
+	ticket   TEXT NOT NULL,
+	series   TEXT,
+	UNIQUE(draw_id, position, ticket)
+);
+CREATE INDEX IF NOT EXISTS idx_draws_date ON draws(draw_date);
+CREATE INDEX IF NOT EXISTS idx_prizes_ticket ON prizes(ticket);
+`
+
+// Manifest describes the contents of a .lottery.zip archive: every draw it
+// contains, plus the relative path to that draw's original PDF inside the
+// archive when one was captured.
+type Manifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Draws       []Draw    `json:"draws"`
+}
+
+// Draw is one draw's entry in a Manifest.
+type Draw struct {
+	LotteryName string              `json:"lottery_name"`
+	DrawDate    string              `json:"draw_date"`
+	PdfLink     string              `json:"pdf_link"`
+	PdfPath     string              `json:"pdf_path,omitempty"`
+	Results     map[string][]string `json:"results"`
+}
+
+// UpsertDraw inserts a draw and its parsed prizes, or updates them in place
+// if the same lottery/date pair has already been archived. Prizes from any
+// previous save of this draw are cleared first, so re-running the parser
+// against a draw whose output changed doesn't leave stale tickets alongside
+// the corrected ones. It is safe to call repeatedly for the same draw. The
+// draw upsert and the prize clear-and-reinsert all run in one transaction,
+// so a failure partway through never leaves a draw with its prizes deleted
+// but not replaced.
+func UpsertDraw(db *sql.DB, lotteryName, drawDate, pdfLink string, scrapedAt time.Time, pdfContent []byte, prizes map[string][]string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO draws (lottery_name, draw_date, pdf_link, scraped_at, pdf_content) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(lottery_name, draw_date) DO UPDATE SET pdf_link = excluded.pdf_link, pdf_content = excluded.pdf_content`,
+		lotteryName, drawDate, pdfLink, scrapedAt, pdfContent,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert draw: %w", err)
+	}
+
+	var drawID int64
+	if err := tx.QueryRow(
+		`SELECT id FROM draws WHERE lottery_name = ? AND draw_date = ?`, lotteryName, drawDate,
+	).Scan(&drawID); err != nil {
+		return fmt.Errorf("failed to look up draw id: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM prizes WHERE draw_id = ?`, drawID); err != nil {
+		return fmt.Errorf("failed to clear old prizes: %w", err)
+	}
+
+	for position, tickets := range prizes {
+		for _, ticket := range tickets {
+			series := ""
+			if position == "Series" {
+				series = ticket
+			}
+			if _, err := tx.Exec(
+				`INSERT OR IGNORE INTO prizes (draw_id, position, ticket, series) VALUES (?, ?, ?, ?)`,
+				drawID, position, ticket, series,
+			); err != nil {
+				return fmt.Errorf("failed to insert prize: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadPrizes returns every prize recorded for drawID, keyed by position.
+func LoadPrizes(db *sql.DB, drawID int64) (map[string][]string, error) {
+	rows, err := db.Query(`SELECT position, ticket FROM prizes WHERE draw_id = ?`, drawID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prizes for draw %d: %w", drawID, err)
+	}
+	defer rows.Close()
+
+	prizes := make(map[string][]string)
+	for rows.Next() {
+		var position, ticket string
+		if err := rows.Scan(&position, &ticket); err != nil {
+			return nil, fmt.Errorf("failed to scan prize: %w", err)
+		}
+		prizes[position] = append(prizes[position], ticket)
+	}
+	return prizes, rows.Err()
+}
@@ -3,17 +3,14 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/gocolly/colly"
-	"github.com/robfig/cron/v3"
 	"github.com/romanpickl/pdf"
 )
 
@@ -29,9 +26,6 @@ type LotteryResults struct {
 }
 
 var (
-	lotteryResults   LotteryResults
-	lotteryListCache []WebScrape
-
 	numbersRegex      = regexp.MustCompile(`\d+`)
 	alphanumericRegex = regexp.MustCompile(`\[([A-Z]+ \d+)\]`)
 	seriesRegex       = regexp.MustCompile(`\[([A-Z])\]`)
@@ -54,15 +48,6 @@ var (
 	contentType   = "application/json"
 )
 
-func scheduleDailyCheck() {
-	c := cron.New(cron.WithLocation(time.FixedZone("IST", 5*60*60+30*60)))
-	_, err := c.AddFunc("15 16 * * *", checkAndRefreshData)
-	if err != nil {
-		log.Fatalf("Failed to schedule cron job: %v", err)
-	}
-	c.Start()
-}
-
 func saveDataToFile(filename string, data interface{}) error {
 	jsonData, err := json.MarshalIndent(data, "", "    ")
 	if err != nil {
@@ -79,170 +64,6 @@ func loadDataFromFile(filename string, data interface{}) error {
 	return json.Unmarshal(jsonData, data)
 }
 
-func crawlAndSaveResults(firstVisit bool) error {
-	lotteryList, err := getLotteryList(firstVisit)
-	if err != nil {
-		return fmt.Errorf("failed to fetch lottery list: %w", err)
-	}
-	if len(lotteryList) == 0 {
-		return fmt.Errorf("no lottery list found")
-	}
-
-	// Update last updated date
-	lotteryResults.LastUpdated, _ = time.Parse("02/01/2006", lotteryList[0].LotteryDate)
-	lotteryListCache = lotteryList
-
-	// Process lottery results concurrently
-	results, err := processLotteryResults(lotteryList)
-	if err != nil {
-		return err
-	}
-
-	// Save results to file
-	if err := saveResults(results); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-
-func processLotteryResults(lotteryList []WebScrape) (map[string]map[string][]string, error) {
-	results := make(map[string]map[string][]string)
-	resultChan := make(chan struct {
-		lotteryName string
-		data        map[string][]string
-		err         error
-	}, len(lotteryList))
-
-	for _, lottery := range lotteryList {
-		go func(lottery WebScrape) {
-			data, err := processLottery(lottery)
-			resultChan <- struct {
-				lotteryName string
-				data        map[string][]string
-				err         error
-			}{lotteryName: lottery.LotteryName, data: data, err: err}
-		}(lottery)
-	}
-
-	for range lotteryList {
-		result := <-resultChan
-		if result.err != nil {
-			log.Printf("Error processing lottery %s: %v", result.lotteryName, result.err)
-			continue
-		}
-		results[result.lotteryName] = result.data
-	}
-
-	if len(results) == 0 {
-		return nil, fmt.Errorf("no results found")
-	}
-
-	return results, nil
-}
-
-
-func processLottery(lottery WebScrape) (map[string][]string, error) {
-	if lottery.LotteryName == "" {
-		return nil, nil
-	}
-
-	resp, err := http.Get(lottery.PdfLink)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download PDF for %s: %v", lottery.LotteryName, err)
-	}
-	defer resp.Body.Close()
-
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read PDF content for %s: %v", lottery.LotteryName, err)
-	}
-
-	text, err := ExtractTextFromPDFContent(content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract text from PDF for %s: %v", lottery.LotteryName, err)
-	}
-
-	return parseLotteryNumbers(text), nil
-}
-
-
-func saveResults(results map[string]map[string][]string) error {
-	if len(results) == 0 {
-		return fmt.Errorf("no results to save")
-	}
-
-	lotteryResults.Results = results
-	if err := saveDataToFile(resultsFile, lotteryResults); err != nil {
-		return fmt.Errorf("failed to save lottery results: %w", err)
-	}
-
-	log.Println("Refreshed lottery results")
-	return nil
-}
-
-func checkAndRefreshData() {
-	loc, err := time.LoadLocation("Asia/Kolkata")
-	if err != nil {
-		log.Fatalf("Failed to load IST location: %v", err)
-	}
-	now := time.Now().In(loc)
-	today3pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 15, 0, 0, loc)
-	if lotteryResults.LastUpdated.Before(today3pm) && now.After(today3pm) {
-		log.Println("Data is outdated, refreshing...")
-		if err := crawlAndSaveResults(false); err != nil {
-			log.Printf("Failed to refresh data: %v", err)
-		}
-		log.Println("Data has been refreshed")
-	} else {
-		log.Println("Data is up-to-date")
-	}
-}
-
-func getLotteryList(firstVisit bool) ([]WebScrape, error) {
-	var datas []WebScrape
-	now := time.Now().Local()
-	today3pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 15, 0, 0, now.Location())
-	c := colly.NewCollector(colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.3"))
-
-	c.OnHTML("tr", func(e *colly.HTMLElement) {
-		href := e.ChildAttr("td a", "href")
-		text := e.ChildText("td:first-child")
-		text2 := e.ChildText("td:nth-child(2)")
-		if text != "" {
-			datas = append(datas, WebScrape{LotteryName: text, LotteryDate: text2, PdfLink: href})
-		}
-	})
-
-	if firstVisit {
-		c.Visit("https://statelottery.kerala.gov.in/index.php/lottery-result-view")
-		return datas, nil
-	}
-
-	for {
-		c.Visit("https://statelottery.kerala.gov.in/index.php/lottery-result-view")
-		if len(datas) == 0 {
-			log.Println("Error fetching lottery list, retrying...")
-			time.Sleep(time.Minute * 10)
-			continue
-		}
-		latestDate, err := time.Parse("02/01/2006", datas[0].LotteryDate)
-		if err != nil {
-			return nil, err
-		} else if latestDate.Day() >= now.Day() || lotteryResults.LastUpdated.Day() < latestDate.Day() {
-			lotteryResults.LastUpdated = latestDate
-			break
-		} else if latestDate.Day() <= now.Day() && now.Before(today3pm) {
-			log.Println("current data is up to date...")
-			break
-		}
-		log.Println("Latest data not available, checking again in 15 minutes...")
-		time.Sleep(time.Minute * 15)
-	}
-	return datas, nil
-}
-
 func parseLotteryNumbers(input string) map[string][]string {
 	result := make(map[string][]string)
 	parts := strings.Split(input, "<")
@@ -343,38 +164,6 @@ func ExtractTextFromPDFContent(content []byte) (string, error) {
 	return ProcessTextContent(finalString)
 }
 
-func getAllResults(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set(contentHeader, contentType)
-	json.NewEncoder(w).Encode(lotteryResults)
-}
-
-func listLotteries(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set(contentHeader, contentType)
-	json.NewEncoder(w).Encode(lotteryListCache)
-}
-
-func checkTickets(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set(contentHeader, contentType)
-	var tickets []string
-	if err := json.NewDecoder(r.Body).Decode(&tickets); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		return
-	}
-
-	winners := make(map[string]map[string][]string)
-	for lotteryName, results := range lotteryResults.Results {
-		currentWinners := checkWinningTickets(results, tickets)
-		for pos, winningTickets := range currentWinners {
-			if winners[pos] == nil {
-				winners[pos] = make(map[string][]string)
-			}
-			winners[pos][lotteryName] = append(winners[pos][lotteryName], winningTickets...)
-		}
-	}
-
-	json.NewEncoder(w).Encode(winners)
-}
-
 func checkWinningTickets(results map[string][]string, tickets []string) map[string][]string {
 	winners := make(map[string][]string)
 	series := results["Series"]
@@ -413,42 +202,82 @@ func isWinningTicket(ticket string, nums []string) bool {
 	return false
 }
 
+func newResultStore(kind, path string) (ResultStore, error) {
+	switch kind {
+	case "sqlite", "":
+		if path == "" {
+			path = dbFile
+		}
+		return NewSQLiteStore(path)
+	case "file":
+		if path == "" {
+			path = resultsFile
+		}
+		return NewFileStore(path)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", kind)
+	}
+}
+
+func newSource(kind, fakeDir string) (Source, error) {
+	switch kind {
+	case "kerala", "":
+		return NewKeralaSource(), nil
+	case "fake":
+		return &FakeSource{Dir: fakeDir}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q", kind)
+	}
+}
+
 func main() {
-	// Load existing data from file, if available
-	err := loadDataFromFile(resultsFile, &lotteryResults)
+	storeKind := flag.String("store", "sqlite", "result store backend: sqlite or file")
+	storePath := flag.String("store-path", "", "path for the result store (defaults to lottery.db or results.json)")
+	sourceKind := flag.String("source", "kerala", "lottery source: kerala or fake")
+	fakeSourceDir := flag.String("fake-source-dir", "testdata/fake-source", "directory the fake source reads draws/PDFs from")
+	addr := flag.String("addr", ":8080", "address to serve HTTP on")
+	flag.Parse()
+
+	resultStore, err := newResultStore(*storeKind, *storePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s store: %v", *storeKind, err)
+	}
+
+	source, err := newSource(*sourceKind, *fakeSourceDir)
 	if err != nil {
+		log.Fatalf("Failed to set up %s source: %v", *sourceKind, err)
+	}
+
+	loadSubscriptions()
+	startNotifyWorkers()
+
+	server := NewServer(resultStore, source, *addr)
+
+	// Load existing data from file, if available
+	loadErr := loadDataFromFile(resultsFile, &server.lotteryResults)
+	if loadErr != nil {
 		log.Printf("%s not found or failed to load, attempting initial crawl...", resultsFile)
 	} else {
 		log.Printf("Loaded existing data from %s", resultsFile)
 	}
 
 	// Start the server immediately to serve any available data
-	go func() {
-		http.HandleFunc("/results", getAllResults)
-		http.HandleFunc("/lotteries", listLotteries)
-		http.HandleFunc("/check-tickets", checkTickets)
-
-		fs := http.FileServer(http.Dir("./public"))
-		http.Handle("/", fs)
-
-		log.Println("Starting server on :8080...")
-		log.Fatal(http.ListenAndServe(":8080", nil))
-	}()
+	server.Run()
 
 	// Perform initial crawl and refresh
-	if err == nil {
+	if loadErr == nil {
 		log.Println("Checking for new lotteries on startup...")
-		if crawlErr := crawlAndSaveResults(false); crawlErr != nil {
+		if crawlErr := server.crawlAndSaveResults(false); crawlErr != nil {
 			log.Printf("Failed to check for new lotteries on startup: %v", crawlErr)
 		}
 	} else {
-		if crawlErr := crawlAndSaveResults(true); crawlErr != nil {
+		if crawlErr := server.crawlAndSaveResults(true); crawlErr != nil {
 			log.Fatalf("Failed to crawl and save results: %v", crawlErr)
 		}
 	}
 
 	// Schedule daily checks using cron
-	scheduleDailyCheck()
+	server.scheduleDailyCheck()
 
 	// Keep the main goroutine alive
 	select {}
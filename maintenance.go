@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stateDir holds the maintenance-mode state files. Their mere presence
+// controls scraper behaviour, so operators can pause refreshes without a
+// redeploy - useful for the Kerala site's known outages and for controlled
+// cutovers.
+const stateDir = "state"
+
+var (
+	disabledStateFile = filepath.Join(stateDir, "disabled")
+	untilStateFile    = filepath.Join(stateDir, "until")
+)
+
+// maintenanceStatus reports whether refreshes are currently suspended and,
+// if suspended by an "until" deadline, when they'll resume.
+type maintenanceStatus struct {
+	Paused bool       `json:"paused"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// isPaused checks the maintenance state files: the presence of
+// state/disabled suspends refreshes unconditionally, and state/until
+// suspends them until the RFC3339 timestamp inside it has passed.
+func isPaused() (maintenanceStatus, error) {
+	if _, err := os.Stat(disabledStateFile); err == nil {
+		return maintenanceStatus{Paused: true}, nil
+	} else if !os.IsNotExist(err) {
+		return maintenanceStatus{}, fmt.Errorf("failed to stat %s: %w", disabledStateFile, err)
+	}
+
+	content, err := os.ReadFile(untilStateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return maintenanceStatus{}, nil
+		}
+		return maintenanceStatus{}, fmt.Errorf("failed to read %s: %w", untilStateFile, err)
+	}
+
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if err != nil {
+		return maintenanceStatus{}, fmt.Errorf("failed to parse %s: %w", untilStateFile, err)
+	}
+	if time.Now().Before(until) {
+		return maintenanceStatus{Paused: true, Until: &until}, nil
+	}
+	return maintenanceStatus{}, nil
+}
+
+// authorizedAdmin checks the shared secret configured via the ADMIN_SECRET
+// env var against the request's X-Admin-Secret header, using a
+// constant-time comparison so the check doesn't leak the secret through
+// response-time timing. Admin endpoints are denied by default when no
+// secret is configured.
+func authorizedAdmin(r *http.Request) bool {
+	secret := os.Getenv("ADMIN_SECRET")
+	if secret == "" {
+		return false
+	}
+	got := r.Header.Get("X-Admin-Secret")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(secret)) == 1
+}
+
+func (s *Server) adminPause(w http.ResponseWriter, r *http.Request) {
+	if !authorizedAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		http.Error(w, "failed to pause", http.StatusInternalServerError)
+		return
+	}
+
+	until := r.URL.Query().Get("until")
+	if until == "" {
+		if err := os.WriteFile(disabledStateFile, nil, 0644); err != nil {
+			http.Error(w, "failed to pause", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, until); err != nil {
+		http.Error(w, "until must be an RFC3339 timestamp", http.StatusBadRequest)
+		return
+	}
+	if err := os.WriteFile(untilStateFile, []byte(until), 0644); err != nil {
+		http.Error(w, "failed to pause", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminResume(w http.ResponseWriter, r *http.Request) {
+	if !authorizedAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	for _, f := range []string{disabledStateFile, untilStateFile} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			http.Error(w, "failed to resume", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+
+	status, err := isPaused()
+	if err != nil {
+		http.Error(w, "failed to check maintenance state", http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.RLock()
+	lastCrawl := s.lastCrawlAt
+	s.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(struct {
+		LastSuccessfulCrawl time.Time         `json:"last_successful_crawl"`
+		NextScheduledCrawl  time.Time         `json:"next_scheduled_crawl"`
+		Maintenance         maintenanceStatus `json:"maintenance"`
+	}{
+		LastSuccessfulCrawl: lastCrawl,
+		NextScheduledCrawl:  nextScheduledCrawl(),
+		Maintenance:         status,
+	})
+}
+
+// nextScheduledCrawl mirrors the "15 16 * * *" IST cron schedule used by
+// scheduleDailyCheck.
+func nextScheduledCrawl() time.Time {
+	loc := time.FixedZone("IST", 5*60*60+30*60)
+	now := time.Now().In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), 16, 15, 0, 0, loc)
+	if !now.Before(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Server bundles the mutable state and pluggable backends that used to live
+// in package-level globals (lotteryResults, lotteryListCache), so different
+// store/source configurations - including fakes used in tests - can run
+// side by side without stepping on each other.
+type Server struct {
+	store  ResultStore
+	source Source
+	addr   string
+
+	mu               sync.RWMutex
+	lotteryResults   LotteryResults
+	lotteryListCache []WebScrape
+	lastCrawlAt      time.Time
+	updateCh         chan struct{}
+}
+
+// NewServer wires a Server around the given store/source backends.
+func NewServer(store ResultStore, source Source, addr string) *Server {
+	return &Server{
+		store:          store,
+		source:         source,
+		addr:           addr,
+		lotteryResults: LotteryResults{Results: make(map[string]map[string][]string)},
+		updateCh:       make(chan struct{}),
+	}
+}
+
+// Run starts the HTTP server in the background so it can serve any data
+// already on hand while the initial crawl runs.
+func (s *Server) Run() {
+	go func() {
+		http.HandleFunc("/results", s.getAllResults)
+		http.HandleFunc("GET /results/{lottery}", s.lotteryHistory)
+		http.HandleFunc("GET /ticket/{ticket}/history", s.ticketHistory)
+		http.HandleFunc("/lotteries", s.listLotteries)
+		http.HandleFunc("/check-tickets", s.checkTickets)
+		http.HandleFunc("POST /subscriptions", createSubscription)
+		http.HandleFunc("GET /subscriptions/{id}", getSubscription)
+		http.HandleFunc("DELETE /subscriptions/{id}", deleteSubscription)
+		http.HandleFunc("POST /admin/pause", s.adminPause)
+		http.HandleFunc("POST /admin/resume", s.adminResume)
+		http.HandleFunc("GET /admin/status", s.adminStatus)
+		http.HandleFunc("GET /results/stream", s.streamResults)
+		http.HandleFunc("GET /results/wait", s.waitResults)
+		http.HandleFunc("POST /admin/export", s.adminExport)
+		http.HandleFunc("POST /admin/import", s.adminImport)
+
+		fs := http.FileServer(http.Dir("./public"))
+		http.Handle("/", fs)
+
+		log.Printf("Starting server on %s...", s.addr)
+		log.Fatal(http.ListenAndServe(s.addr, nil))
+	}()
+}
+
+func (s *Server) scheduleDailyCheck() {
+	c := cron.New(cron.WithLocation(time.FixedZone("IST", 5*60*60+30*60)))
+	_, err := c.AddFunc("15 16 * * *", s.checkAndRefreshData)
+	if err != nil {
+		log.Fatalf("Failed to schedule cron job: %v", err)
+	}
+	c.Start()
+}
+
+func (s *Server) crawlAndSaveResults(firstVisit bool) error {
+	ctx := context.Background()
+	lotteryList, err := s.listDrawsFresh(ctx, firstVisit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch lottery list: %w", err)
+	}
+	if len(lotteryList) == 0 {
+		return fmt.Errorf("no lottery list found")
+	}
+
+	lastUpdated, _ := time.Parse("02/01/2006", lotteryList[0].LotteryDate)
+
+	s.mu.Lock()
+	s.lotteryListCache = lotteryList
+	s.mu.Unlock()
+
+	results, pdfs, err := s.processLotteryResults(ctx, lotteryList)
+	if err != nil {
+		return err
+	}
+
+	if err := s.saveResults(lastUpdated, results); err != nil {
+		return err
+	}
+
+	// Archive every draw we have data for, original PDF included, so
+	// history is never lost even though s.lotteryResults only keeps the
+	// latest one in memory.
+	if s.store != nil {
+		for _, lottery := range lotteryList {
+			data, ok := results[lottery.LotteryName]
+			if !ok {
+				continue
+			}
+			if err := s.store.SaveDraw(lottery, data, pdfs[lottery.LotteryName]); err != nil {
+				log.Printf("Failed to archive draw for %s: %v", lottery.LotteryName, err)
+			}
+		}
+	}
+
+	notifySubscriptions(results)
+
+	s.mu.Lock()
+	s.lastCrawlAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// listDrawsFresh mirrors the scraper's original wait-for-3:15pm polling: on
+// the very first crawl it returns whatever's currently listed, otherwise it
+// polls the source until a newer draw than our last known one shows up, or
+// until it's clear today's results simply aren't out yet.
+func (s *Server) listDrawsFresh(ctx context.Context, firstVisit bool) ([]WebScrape, error) {
+	if firstVisit {
+		return s.source.ListDraws(ctx)
+	}
+
+	now := time.Now().Local()
+	today3pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 15, 0, 0, now.Location())
+
+	for {
+		datas, err := s.source.ListDraws(ctx)
+		if err != nil || len(datas) == 0 {
+			log.Println("Error fetching lottery list, retrying...")
+			time.Sleep(time.Minute * 10)
+			continue
+		}
+
+		latestDate, err := time.Parse("02/01/2006", datas[0].LotteryDate)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.RLock()
+		lastUpdated := s.lotteryResults.LastUpdated
+		s.mu.RUnlock()
+
+		if latestDate.Day() >= now.Day() || lastUpdated.Day() < latestDate.Day() {
+			return datas, nil
+		} else if latestDate.Day() <= now.Day() && now.Before(today3pm) {
+			log.Println("current data is up to date...")
+			return datas, nil
+		}
+		log.Println("Latest data not available, checking again in 15 minutes...")
+		time.Sleep(time.Minute * 15)
+	}
+}
+
+// processLotteryResults fetches and parses every lottery's PDF concurrently,
+// returning both the parsed results and the raw PDF bytes (keyed by lottery
+// name) so callers can archive the original document alongside its parse.
+func (s *Server) processLotteryResults(ctx context.Context, lotteryList []WebScrape) (map[string]map[string][]string, map[string][]byte, error) {
+	results := make(map[string]map[string][]string)
+	pdfs := make(map[string][]byte)
+	resultChan := make(chan struct {
+		lotteryName string
+		data        map[string][]string
+		pdf         []byte
+		err         error
+	}, len(lotteryList))
+
+	for _, lottery := range lotteryList {
+		go func(lottery WebScrape) {
+			data, pdf, err := s.processLottery(ctx, lottery)
+			resultChan <- struct {
+				lotteryName string
+				data        map[string][]string
+				pdf         []byte
+				err         error
+			}{lotteryName: lottery.LotteryName, data: data, pdf: pdf, err: err}
+		}(lottery)
+	}
+
+	for range lotteryList {
+		result := <-resultChan
+		if result.err != nil {
+			log.Printf("Error processing lottery %s: %v", result.lotteryName, result.err)
+			continue
+		}
+		results[result.lotteryName] = result.data
+		pdfs[result.lotteryName] = result.pdf
+	}
+
+	if len(results) == 0 {
+		return nil, nil, fmt.Errorf("no results found")
+	}
+
+	return results, pdfs, nil
+}
+
+func (s *Server) processLottery(ctx context.Context, lottery WebScrape) (map[string][]string, []byte, error) {
+	if lottery.LotteryName == "" {
+		return nil, nil, nil
+	}
+
+	content, err := s.source.FetchPDF(ctx, lottery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch PDF for %s: %w", lottery.LotteryName, err)
+	}
+
+	text, err := ExtractTextFromPDFContent(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to extract text from PDF for %s: %w", lottery.LotteryName, err)
+	}
+
+	return parseLotteryNumbers(text), content, nil
+}
+
+func (s *Server) saveResults(lastUpdated time.Time, results map[string]map[string][]string) error {
+	if len(results) == 0 {
+		return fmt.Errorf("no results to save")
+	}
+
+	s.mu.Lock()
+	s.lotteryResults.LastUpdated = lastUpdated
+	s.lotteryResults.Results = results
+	snapshot := s.lotteryResults
+	old := s.updateCh
+	s.updateCh = make(chan struct{})
+	s.mu.Unlock()
+	close(old)
+
+	if err := saveDataToFile(resultsFile, snapshot); err != nil {
+		return fmt.Errorf("failed to save lottery results: %w", err)
+	}
+
+	log.Println("Refreshed lottery results")
+	return nil
+}
+
+// waitForUpdate blocks until s.lotteryResults.LastUpdated advances past
+// since, or ctx is done, whichever happens first. Many callers can wait on
+// the same generation of updateCh at once: saveResults closes it to wake
+// them all simultaneously, rather than each caller polling.
+func (s *Server) waitForUpdate(ctx context.Context, since time.Time) (LotteryResults, bool) {
+	for {
+		s.mu.RLock()
+		updateCh := s.updateCh
+		current := s.lotteryResults.LastUpdated
+		s.mu.RUnlock()
+
+		if current.After(since) {
+			return s.snapshot(), true
+		}
+
+		select {
+		case <-updateCh:
+			continue
+		case <-ctx.Done():
+			return LotteryResults{}, false
+		}
+	}
+}
+
+// waitResults serves GET /results/wait?since=<unix>&timeout=30s, a
+// battery-friendly alternative to polling /results: it blocks until a newer
+// draw than since is available, then returns it as JSON, or returns 204 if
+// the deadline elapses first.
+func (s *Server) waitResults(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		secs, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, "since must be a unix timestamp", http.StatusBadRequest)
+			return
+		}
+		since = time.Unix(secs, 0)
+	}
+
+	timeout := 30 * time.Second
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			http.Error(w, "timeout must be a duration like 30s", http.StatusBadRequest)
+			return
+		}
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, ok := s.waitForUpdate(ctx, since)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set(contentHeader, contentType)
+	json.NewEncoder(w).Encode(result)
+}
+
+// streamResults serves GET /results/stream as Server-Sent Events, flushing
+// a new event every time a fresher draw is saved, until the client
+// disconnects.
+func (s *Server) streamResults(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ctx := r.Context()
+	since := time.Time{}
+	for {
+		result, ok := s.waitForUpdate(ctx, since)
+		if !ok {
+			return
+		}
+		since = result.LastUpdated
+
+		payload, err := json.Marshal(result)
+		if err != nil {
+			log.Printf("Failed to marshal SSE payload: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", payload)
+		flusher.Flush()
+	}
+}
+
+func (s *Server) checkAndRefreshData() {
+	status, err := isPaused()
+	if err != nil {
+		log.Printf("Failed to check maintenance state: %v", err)
+	} else if status.Paused {
+		log.Println("Refresh suspended by maintenance state")
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		log.Fatalf("Failed to load IST location: %v", err)
+	}
+	now := time.Now().In(loc)
+	today3pm := time.Date(now.Year(), now.Month(), now.Day(), 16, 15, 0, 0, loc)
+
+	s.mu.RLock()
+	lastUpdated := s.lotteryResults.LastUpdated
+	s.mu.RUnlock()
+
+	if lastUpdated.Before(today3pm) && now.After(today3pm) {
+		log.Println("Data is outdated, refreshing...")
+		if err := s.crawlAndSaveResults(false); err != nil {
+			log.Printf("Failed to refresh data: %v", err)
+		}
+		log.Println("Data has been refreshed")
+	} else {
+		log.Println("Data is up-to-date")
+	}
+}
+
+func (s *Server) snapshot() LotteryResults {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lotteryResults
+}
+
+func (s *Server) listCache() []WebScrape {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lotteryListCache
+}
+
+func (s *Server) getAllResults(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		json.NewEncoder(w).Encode(s.snapshot())
+		return
+	}
+
+	if s.store == nil {
+		http.Error(w, "archive not available", http.StatusServiceUnavailable)
+		return
+	}
+	draws, err := s.store.DrawsOnDate(date)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query results for %s", date), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(draws)
+}
+
+// lotteryHistory serves GET /results/{lottery}?from=YYYY-MM-DD&to=YYYY-MM-DD,
+// querying the archive instead of the latest-draw cache.
+func (s *Server) lotteryHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+	if s.store == nil {
+		http.Error(w, "archive not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	lottery := r.PathValue("lottery")
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" {
+		from = "0000-01-01"
+	}
+	if to == "" {
+		to = "9999-12-31"
+	}
+
+	draws, err := s.store.DrawsBetween(lottery, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query history for %s", lottery), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(draws)
+}
+
+// ticketHistory serves GET /ticket/{ticket}/history, listing every archived
+// draw the ticket won a prize in.
+func (s *Server) ticketHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+	if s.store == nil {
+		http.Error(w, "archive not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ticket := r.PathValue("ticket")
+	draws, err := s.store.TicketHistory(ticket)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query history for ticket %s", ticket), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(draws)
+}
+
+func (s *Server) listLotteries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+	json.NewEncoder(w).Encode(s.listCache())
+}
+
+func (s *Server) checkTickets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+	var tickets []string
+	if err := json.NewDecoder(r.Body).Decode(&tickets); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	winners := make(map[string]map[string][]string)
+	for lotteryName, results := range s.snapshot().Results {
+		currentWinners := checkWinningTickets(results, tickets)
+		for pos, winningTickets := range currentWinners {
+			if winners[pos] == nil {
+				winners[pos] = make(map[string][]string)
+			}
+			winners[pos][lotteryName] = append(winners[pos][lotteryName], winningTickets...)
+		}
+	}
+
+	json.NewEncoder(w).Encode(winners)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitForUpdateBlocksUntilSaveResults(t *testing.T) {
+	s := NewServer(nil, nil, ":0")
+
+	since := s.snapshot().LastUpdated
+	done := make(chan LotteryResults, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, ok := s.waitForUpdate(ctx, since)
+		if !ok {
+			t.Error("waitForUpdate timed out instead of observing the update")
+			return
+		}
+		done <- result
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("waitForUpdate returned before saveResults was called")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	lastUpdated := time.Now()
+	results := map[string]map[string][]string{"Lottery": {"1st": {"AB 123456"}}}
+	if err := s.saveResults(lastUpdated, results); err != nil {
+		t.Fatalf("saveResults: %v", err)
+	}
+
+	select {
+	case result := <-done:
+		if !result.LastUpdated.Equal(lastUpdated) {
+			t.Errorf("LastUpdated = %v, want %v", result.LastUpdated, lastUpdated)
+		}
+		if result.Results["Lottery"]["1st"][0] != "AB 123456" {
+			t.Errorf("unexpected Results: %v", result.Results)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForUpdate never observed saveResults")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gocolly/colly"
+)
+
+// Source abstracts where the list of draws and their PDFs come from, so the
+// Kerala scraper can be swapped out (e.g. for tests, or for another state
+// lottery entirely) without touching the parsing or storage layers.
+type Source interface {
+	ListDraws(ctx context.Context) ([]WebScrape, error)
+	FetchPDF(ctx context.Context, draw WebScrape) ([]byte, error)
+}
+
+// KeralaSource is the original colly-based scraper for the Kerala State
+// Lottery result page.
+type KeralaSource struct {
+	URL string
+}
+
+// NewKeralaSource returns a KeralaSource pointed at the official results
+// page.
+func NewKeralaSource() *KeralaSource {
+	return &KeralaSource{URL: "https://statelottery.kerala.gov.in/index.php/lottery-result-view"}
+}
+
+func (k *KeralaSource) ListDraws(ctx context.Context) ([]WebScrape, error) {
+	var datas []WebScrape
+	c := colly.NewCollector(colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.3"))
+
+	c.OnHTML("tr", func(e *colly.HTMLElement) {
+		href := e.ChildAttr("td a", "href")
+		text := e.ChildText("td:first-child")
+		text2 := e.ChildText("td:nth-child(2)")
+		if text != "" {
+			datas = append(datas, WebScrape{LotteryName: text, LotteryDate: text2, PdfLink: href})
+		}
+	})
+
+	if err := c.Visit(k.URL); err != nil {
+		return nil, fmt.Errorf("failed to visit %s: %w", k.URL, err)
+	}
+	return datas, nil
+}
+
+func (k *KeralaSource) FetchPDF(ctx context.Context, draw WebScrape) ([]byte, error) {
+	resp, err := http.Get(draw.PdfLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download PDF for %s: %w", draw.LotteryName, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download PDF for %s: status %d", draw.LotteryName, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// FakeSource reads a list of draws and their PDF bytes from a local
+// directory instead of the network, so the scraping/parsing pipeline can be
+// exercised without hitting the government site. Dir must contain a
+// manifest.json (a JSON array of WebScrape) plus one PDF per entry, named
+// after the base name of its PdfLink.
+type FakeSource struct {
+	Dir string
+}
+
+func (f *FakeSource) ListDraws(ctx context.Context) ([]WebScrape, error) {
+	var draws []WebScrape
+	if err := loadDataFromFile(filepath.Join(f.Dir, "manifest.json"), &draws); err != nil {
+		return nil, fmt.Errorf("failed to load fake source manifest: %w", err)
+	}
+	return draws, nil
+}
+
+func (f *FakeSource) FetchPDF(ctx context.Context, draw WebScrape) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(f.Dir, filepath.Base(draw.PdfLink)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake PDF for %s: %w", draw.LotteryName, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakeSourceListAndFetch(t *testing.T) {
+	src := &FakeSource{Dir: "testdata/fake-source"}
+
+	draws, err := src.ListDraws(context.Background())
+	if err != nil {
+		t.Fatalf("ListDraws: %v", err)
+	}
+	if len(draws) != 1 {
+		t.Fatalf("expected 1 draw, got %d", len(draws))
+	}
+	if draws[0].LotteryName != "FIFTY-FIFTY FF-1" {
+		t.Errorf("unexpected lottery name %q", draws[0].LotteryName)
+	}
+
+	content, err := src.FetchPDF(context.Background(), draws[0])
+	if err != nil {
+		t.Fatalf("FetchPDF: %v", err)
+	}
+	if len(content) == 0 {
+		t.Fatal("expected non-empty PDF content")
+	}
+
+	text, err := ExtractTextFromPDFContent(content)
+	if err != nil {
+		t.Fatalf("ExtractTextFromPDFContent: %v", err)
+	}
+	results := parseLotteryNumbers(text)
+	if got := results["1st"]; len(got) != 1 || got[0] != "AB 123456" {
+		t.Errorf("unexpected 1st prize result: %v", got)
+	}
+}
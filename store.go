@@ -0,0 +1,242 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Joe-Albert-1703/lottery-scraper/internal/archivestore"
+	_ "modernc.org/sqlite"
+)
+
+// ResultStore is the persistence boundary for scraped draws. SQLiteStore
+// archives full history; FileStore only ever remembers the latest draw per
+// lottery, matching the program's original behaviour. The current draw for
+// /results itself comes from Server.snapshot (the in-memory cache backed by
+// resultsFile), not from here; this interface backs the archive-query
+// endpoints (/results?date=, /results/{lottery}, /tickets/{ticket}/history).
+type ResultStore interface {
+	SaveDraw(lottery WebScrape, data map[string][]string, pdfContent []byte) error
+	DrawsOnDate(date string) ([]DrawRecord, error)
+	DrawsBetween(lotteryName, from, to string) ([]DrawRecord, error)
+	DrawsInRange(from, to string) ([]DrawRecord, error)
+	TicketHistory(ticket string) ([]DrawRecord, error)
+	Close() error
+}
+
+// dbFile is the default location of the SQLite archive. It sits next to
+// resultsFile so existing deployments keep their on-disk layout.
+const dbFile = "lottery.db"
+
+// DrawRecord is a single archived draw together with its parsed prizes.
+// PDFContent holds the original scraped PDF when the backing store kept
+// one; it's excluded from JSON responses since it's only needed internally
+// by the archive export/import path.
+type DrawRecord struct {
+	LotteryName string              `json:"lottery_name"`
+	DrawDate    string              `json:"draw_date"`
+	PdfLink     string              `json:"pdf_link"`
+	ScrapedAt   time.Time           `json:"scraped_at"`
+	Results     map[string][]string `json:"results"`
+	PDFContent  []byte              `json:"-"`
+}
+
+// SQLiteStore is the SQLite-backed archive of every draw ever scraped. Unlike
+// lotteryResults, which only ever holds the latest draw per lottery, SQLiteStore
+// keeps full history so past results can be queried by date, lottery or
+// ticket.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the SQLite database at path
+// and ensures its schema is up to date.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate store: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	_, err := s.db.Exec(archivestore.Schema)
+	return err
+}
+
+// SaveDraw inserts a scraped draw, its original PDF and its parsed prizes,
+// or updates them in place if the same lottery/date pair has already been
+// archived. It is safe to call repeatedly for the same draw. Keeping
+// pdfContent makes an export of this draw self-contained and re-parseable
+// offline if parseLotteryNumbers changes later. The insert/update itself is
+// shared with cmd/lotteryball via archivestore, so the two can't drift.
+func (s *SQLiteStore) SaveDraw(lottery WebScrape, data map[string][]string, pdfContent []byte) error {
+	drawDate, err := time.Parse("02/01/2006", lottery.LotteryDate)
+	if err != nil {
+		return fmt.Errorf("failed to parse draw date %q: %w", lottery.LotteryDate, err)
+	}
+	dateKey := drawDate.Format("2006-01-02")
+
+	return archivestore.UpsertDraw(s.db, lottery.LotteryName, dateKey, lottery.PdfLink, time.Now().UTC(), pdfContent, data)
+}
+
+// DrawsOnDate returns every draw archived for the given YYYY-MM-DD date.
+func (s *SQLiteStore) DrawsOnDate(date string) ([]DrawRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, lottery_name, draw_date, pdf_link, scraped_at, pdf_content FROM draws WHERE draw_date = ?`, date,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draws for %s: %w", date, err)
+	}
+	defer rows.Close()
+	return s.scanDraws(rows)
+}
+
+// DrawsBetween returns every archived draw for a lottery within [from, to]
+// (both YYYY-MM-DD), ordered oldest first.
+func (s *SQLiteStore) DrawsBetween(lotteryName, from, to string) ([]DrawRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, lottery_name, draw_date, pdf_link, scraped_at, pdf_content FROM draws
+		 WHERE lottery_name = ? AND draw_date BETWEEN ? AND ? ORDER BY draw_date ASC`,
+		lotteryName, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draws for %s: %w", lotteryName, err)
+	}
+	defer rows.Close()
+	return s.scanDraws(rows)
+}
+
+// DrawsInRange returns every archived draw across all lotteries within
+// [from, to] (both YYYY-MM-DD), ordered oldest first. It backs the export
+// path, which isn't scoped to a single lottery.
+func (s *SQLiteStore) DrawsInRange(from, to string) ([]DrawRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, lottery_name, draw_date, pdf_link, scraped_at, pdf_content FROM draws
+		 WHERE draw_date BETWEEN ? AND ? ORDER BY draw_date ASC`,
+		from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draws between %s and %s: %w", from, to, err)
+	}
+	defer rows.Close()
+	return s.scanDraws(rows)
+}
+
+// TicketHistory returns every archived draw in which ticket appears as a
+// prize-winning number, ordered oldest first.
+func (s *SQLiteStore) TicketHistory(ticket string) ([]DrawRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT DISTINCT d.id, d.lottery_name, d.draw_date, d.pdf_link, d.scraped_at, d.pdf_content
+		 FROM draws d JOIN prizes p ON p.draw_id = d.id
+		 WHERE p.ticket = ? ORDER BY d.draw_date ASC`, ticket,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ticket history for %s: %w", ticket, err)
+	}
+	defer rows.Close()
+	return s.scanDraws(rows)
+}
+
+func (s *SQLiteStore) scanDraws(rows *sql.Rows) ([]DrawRecord, error) {
+	var records []DrawRecord
+	for rows.Next() {
+		var id int64
+		var rec DrawRecord
+		if err := rows.Scan(&id, &rec.LotteryName, &rec.DrawDate, &rec.PdfLink, &rec.ScrapedAt, &rec.PDFContent); err != nil {
+			return nil, fmt.Errorf("failed to scan draw: %w", err)
+		}
+		prizes, err := s.loadPrizes(id)
+		if err != nil {
+			return nil, err
+		}
+		rec.Results = prizes
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (s *SQLiteStore) scanDraw(row *sql.Row) (DrawRecord, error) {
+	var id int64
+	var rec DrawRecord
+	if err := row.Scan(&id, &rec.LotteryName, &rec.DrawDate, &rec.PdfLink, &rec.ScrapedAt, &rec.PDFContent); err != nil {
+		return DrawRecord{}, fmt.Errorf("failed to scan draw: %w", err)
+	}
+	prizes, err := s.loadPrizes(id)
+	if err != nil {
+		return DrawRecord{}, err
+	}
+	rec.Results = prizes
+	return rec, nil
+}
+
+func (s *SQLiteStore) loadPrizes(drawID int64) (map[string][]string, error) {
+	return archivestore.LoadPrizes(s.db, drawID)
+}
+
+// Close releases the underlying database handle.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// FileStore persists only the latest draw per lottery to a JSON file,
+// mirroring the program's original saveDataToFile/loadDataFromFile
+// behaviour. It exists as a lightweight ResultStore for deployments that
+// don't need a full history.
+type FileStore struct {
+	path string
+
+	mu   sync.RWMutex
+	data LotteryResults
+}
+
+// NewFileStore loads path if it exists, or starts empty otherwise.
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{path: path, data: LotteryResults{Results: make(map[string]map[string][]string)}}
+	if err := loadDataFromFile(path, &fs.data); err != nil {
+		fs.data = LotteryResults{Results: make(map[string]map[string][]string)}
+	}
+	return fs, nil
+}
+
+func (fs *FileStore) SaveDraw(lottery WebScrape, data map[string][]string, pdfContent []byte) error {
+	drawDate, err := time.Parse("02/01/2006", lottery.LotteryDate)
+	if err != nil {
+		return fmt.Errorf("failed to parse draw date %q: %w", lottery.LotteryDate, err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.data.Results[lottery.LotteryName] = data
+	if drawDate.After(fs.data.LastUpdated) {
+		fs.data.LastUpdated = drawDate
+	}
+	return saveDataToFile(fs.path, fs.data)
+}
+
+func (fs *FileStore) DrawsOnDate(date string) ([]DrawRecord, error) {
+	return nil, fmt.Errorf("file store keeps only the latest draw, not a queryable history")
+}
+
+func (fs *FileStore) DrawsBetween(lotteryName, from, to string) ([]DrawRecord, error) {
+	return nil, fmt.Errorf("file store keeps only the latest draw, not a queryable history")
+}
+
+func (fs *FileStore) DrawsInRange(from, to string) ([]DrawRecord, error) {
+	return nil, fmt.Errorf("file store keeps only the latest draw, not a queryable history")
+}
+
+func (fs *FileStore) TicketHistory(ticket string) ([]DrawRecord, error) {
+	return nil, fmt.Errorf("file store keeps only the latest draw, not a queryable history")
+}
+
+func (fs *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NotifyTarget describes how a subscriber wants to be told about a win.
+type NotifyTarget struct {
+	WebhookURL string `json:"webhook_url,omitempty"`
+	Email      string `json:"email,omitempty"`
+}
+
+// Subscription is a standing request to be notified whenever one of Tickets
+// wins a prize in a future draw, so callers don't have to poll
+// /check-tickets themselves.
+type Subscription struct {
+	ID      string       `json:"id"`
+	Tickets []string     `json:"tickets"`
+	Notify  NotifyTarget `json:"notify"`
+}
+
+// WinNotification is the payload delivered to a subscriber for each ticket
+// that won a prize in a newly-scraped draw.
+type WinNotification struct {
+	LotteryName string `json:"lottery_name"`
+	Ticket      string `json:"ticket"`
+	Position    string `json:"position"`
+}
+
+var (
+	subscriptionsFile = "subscriptions.json"
+	subscriptions     = make(map[string]Subscription)
+	subscriptionsMu   sync.RWMutex
+
+	notifyQueue = make(chan notifyJob, 100)
+)
+
+type notifyJob struct {
+	sub  Subscription
+	wins []WinNotification
+}
+
+const (
+	notifyWorkers  = 4
+	notifyRetries  = 3
+	notifyBaseWait = time.Second
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookClient bounds how long a single webhook delivery attempt can take,
+// so one unresponsive subscriber can't tie up a notify worker indefinitely.
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// startNotifyWorkers launches the fixed-size pool that delivers
+// webhook/email notifications, so a slow subscriber endpoint can't block the
+// cron tick that discovered the win.
+func startNotifyWorkers() {
+	for i := 0; i < notifyWorkers; i++ {
+		go func() {
+			for job := range notifyQueue {
+				deliverNotification(job.sub, job.wins)
+			}
+		}()
+	}
+}
+
+func loadSubscriptions() {
+	var stored map[string]Subscription
+	if err := loadDataFromFile(subscriptionsFile, &stored); err != nil {
+		log.Printf("%s not found or failed to load, starting with no subscriptions", subscriptionsFile)
+		return
+	}
+	subscriptionsMu.Lock()
+	subscriptions = stored
+	subscriptionsMu.Unlock()
+}
+
+func saveSubscriptions() error {
+	subscriptionsMu.RLock()
+	defer subscriptionsMu.RUnlock()
+	return saveDataToFile(subscriptionsFile, subscriptions)
+}
+
+func newSubscriptionID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate subscription id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func createSubscription(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+
+	var payload struct {
+		Tickets []string     `json:"tickets"`
+		Notify  NotifyTarget `json:"notify"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if len(payload.Tickets) == 0 {
+		http.Error(w, "tickets must not be empty", http.StatusBadRequest)
+		return
+	}
+	if payload.Notify.WebhookURL == "" && payload.Notify.Email == "" {
+		http.Error(w, "notify.webhook_url or notify.email is required", http.StatusBadRequest)
+		return
+	}
+	if payload.Notify.WebhookURL != "" {
+		if err := validateWebhookURL(payload.Notify.WebhookURL); err != nil {
+			http.Error(w, fmt.Sprintf("notify.webhook_url: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id, err := newSubscriptionID()
+	if err != nil {
+		http.Error(w, "failed to create subscription", http.StatusInternalServerError)
+		return
+	}
+
+	sub := Subscription{ID: id, Tickets: payload.Tickets, Notify: payload.Notify}
+	subscriptionsMu.Lock()
+	subscriptions[id] = sub
+	subscriptionsMu.Unlock()
+
+	if err := saveSubscriptions(); err != nil {
+		log.Printf("Failed to persist subscriptions: %v", err)
+	}
+
+	json.NewEncoder(w).Encode(sub)
+}
+
+func getSubscription(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentHeader, contentType)
+	id := r.PathValue("id")
+
+	subscriptionsMu.RLock()
+	sub, ok := subscriptions[id]
+	subscriptionsMu.RUnlock()
+	if !ok {
+		http.Error(w, "subscription not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(sub)
+}
+
+func deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	subscriptionsMu.Lock()
+	_, ok := subscriptions[id]
+	delete(subscriptions, id)
+	subscriptionsMu.Unlock()
+	if !ok {
+		http.Error(w, "subscription not found", http.StatusNotFound)
+		return
+	}
+
+	if err := saveSubscriptions(); err != nil {
+		log.Printf("Failed to persist subscriptions: %v", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifySubscriptions checks the draws produced by the crawl that just
+// finished (not the whole archive) against every subscription, queueing a
+// notification for any ticket that won.
+func notifySubscriptions(results map[string]map[string][]string) {
+	subscriptionsMu.RLock()
+	subs := make([]Subscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		subs = append(subs, sub)
+	}
+	subscriptionsMu.RUnlock()
+
+	for _, sub := range subs {
+		var wins []WinNotification
+		for lotteryName, drawResults := range results {
+			winners := checkWinningTickets(drawResults, sub.Tickets)
+			for position, tickets := range winners {
+				for _, ticket := range tickets {
+					wins = append(wins, WinNotification{LotteryName: lotteryName, Ticket: ticket, Position: position})
+				}
+			}
+		}
+		if len(wins) == 0 {
+			continue
+		}
+		select {
+		case notifyQueue <- notifyJob{sub: sub, wins: wins}:
+		default:
+			log.Printf("Notify queue full, dropping notification for subscription %s", sub.ID)
+		}
+	}
+}
+
+func deliverNotification(sub Subscription, wins []WinNotification) {
+	if sub.Notify.WebhookURL != "" {
+		if err := deliverWebhook(sub.Notify.WebhookURL, wins); err != nil {
+			log.Printf("Failed to deliver webhook for subscription %s: %v", sub.ID, err)
+		}
+	}
+	if sub.Notify.Email != "" {
+		if err := deliverEmail(sub.Notify.Email, wins); err != nil {
+			log.Printf("Failed to deliver email for subscription %s: %v", sub.ID, err)
+		}
+	}
+}
+
+// validateWebhookURL rejects anything that isn't a plain http/https URL
+// resolving to a public address, so a subscriber can't register a webhook
+// that makes this server fetch loopback, private, link-local, or cloud
+// metadata addresses (e.g. 169.254.169.254) on their behalf.
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("url must have a host")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve host: %w", err)
+	}
+	for _, ip := range ips {
+		if isDisallowedWebhookIP(ip) {
+			return fmt.Errorf("host resolves to a disallowed address (%s)", ip)
+		}
+	}
+	return nil
+}
+
+func isDisallowedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+func deliverWebhook(webhookURL string, wins []WinNotification) error {
+	// Re-validate at delivery time, not just at subscription creation: DNS
+	// for the webhook host can change (or rebind) between the two.
+	if err := validateWebhookURL(webhookURL); err != nil {
+		return fmt.Errorf("refusing to deliver webhook: %w", err)
+	}
+
+	body, err := json.Marshal(wins)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	wait := notifyBaseWait
+	var lastErr error
+	for attempt := 0; attempt < notifyRetries; attempt++ {
+		resp, err := webhookClient.Post(webhookURL, contentType, bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+	return lastErr
+}
+
+func deliverEmail(to string, wins []WinNotification) error {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return fmt.Errorf("SMTP_HOST not configured")
+	}
+	addr := host + ":" + os.Getenv("SMTP_PORT")
+	from := os.Getenv("SMTP_FROM")
+
+	var body strings.Builder
+	body.WriteString("Subject: Lottery winning ticket!\r\n\r\n")
+	for _, win := range wins {
+		fmt.Fprintf(&body, "%s: ticket %s won %s prize\r\n", win.LotteryName, win.Ticket, win.Position)
+	}
+	msg := []byte(body.String())
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	wait := notifyBaseWait
+	var lastErr error
+	for attempt := 0; attempt < notifyRetries; attempt++ {
+		if err := smtp.SendMail(addr, auth, from, []string{to}, msg); err == nil {
+			return nil
+		} else {
+			lastErr = err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+	return lastErr
+}
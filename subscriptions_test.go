@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotifySubscriptionsDoesNotBlockOnFullQueue(t *testing.T) {
+	subscriptionsMu.Lock()
+	subscriptions = make(map[string]Subscription)
+	subscriptionsMu.Unlock()
+	defer func() {
+		subscriptionsMu.Lock()
+		subscriptions = make(map[string]Subscription)
+		subscriptionsMu.Unlock()
+	}()
+
+	// Fill the notify queue so a non-blocking send has nowhere to go, then
+	// make sure notifySubscriptions still returns instead of blocking the
+	// caller (the crawl, in production).
+	for len(notifyQueue) < cap(notifyQueue) {
+		notifyQueue <- notifyJob{}
+	}
+	defer func() {
+		for len(notifyQueue) > 0 {
+			<-notifyQueue
+		}
+	}()
+
+	subscriptionsMu.Lock()
+	subscriptions["sub1"] = Subscription{
+		ID:      "sub1",
+		Tickets: []string{"AB 123456"},
+		Notify:  NotifyTarget{WebhookURL: "http://example.invalid/webhook"},
+	}
+	subscriptionsMu.Unlock()
+
+	results := map[string]map[string][]string{"Lottery": {"1st": {"AB 123456"}}}
+
+	done := make(chan struct{})
+	go func() {
+		notifySubscriptions(results)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("notifySubscriptions blocked on a full notify queue")
+	}
+}
+
+// TestWebhookClientHasTimeout guards against reintroducing http.DefaultClient
+// (no timeout), which let one unresponsive subscriber hang a notify worker
+// indefinitely.
+func TestWebhookClientHasTimeout(t *testing.T) {
+	if webhookClient.Timeout <= 0 {
+		t.Fatalf("webhookClient.Timeout = %v, want a bounded timeout", webhookClient.Timeout)
+	}
+}
+
+func TestValidateWebhookURLRejectsInternalAddresses(t *testing.T) {
+	cases := []string{
+		"http://127.0.0.1/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"ftp://93.184.216.34/hook",
+		"not-a-url",
+	}
+	for _, rawURL := range cases {
+		if err := validateWebhookURL(rawURL); err == nil {
+			t.Errorf("validateWebhookURL(%q) = nil, want an error", rawURL)
+		}
+	}
+}
+
+func TestValidateWebhookURLAcceptsPublicAddress(t *testing.T) {
+	if err := validateWebhookURL("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("validateWebhookURL(public IP) = %v, want nil", err)
+	}
+}